@@ -1,131 +1,126 @@
-package main
+package netpbm
 
 import (
 	"bufio"
 	"errors"
 	"fmt"
+	"image"
+	"image/color"
+	"io"
 	"os"
 	"strconv"
-	"strings"
 )
 
 // PGM représente une image PGM.
 type PGM struct {
-	data         [][]uint8
+	data          [][]uint16
 	width, height int
-	magicNumber  string
-	max          int
+	magicNumber   string
+	max           int
 }
 
-// PBM représente une image PBM.
-type PBM struct {
-	data         [][]int
-	width, height int
-	magicNumber  string
-}
-
-// ReadPGM lit une image PGM depuis un fichier et retourne une structure représentant l'image.
-func ReadPGM(filename string) (*PGM, error) {
-	file, err := os.Open(filename)
+// ReadPGM lit une image PGM (P2 ou P5) depuis r et retourne une structure
+// représentant l'image. r est lu via un headerReader partagé afin que
+// l'en-tête et le raster P5 proviennent exactement du même flux bufferisé
+// (voir ReadPBM). Plusieurs lectures ne s'enchaînent sur un même r, pour des
+// flux Netpbm concaténés, que si r a ReadByte/UnreadByte (un *bufio.Reader
+// par exemple) ; tout autre r est enveloppé dans un nouveau bufio.Reader à
+// chaque appel.
+func ReadPGM(r io.Reader) (*PGM, error) {
+	hr := &headerReader{br: asByteReader(r)}
+
+	magicNumber, err := hr.readToken()
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	var magicNumber string
-
-	// Fonction pour lire la prochaine ligne non commentée
-	readNextLine := func() (string, error) {
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			// Ignorer les lignes vides ou les lignes commençant par "#"
-			if line != "" && !strings.HasPrefix(line, "#") {
-				return line, nil
-			}
-		}
-		return "", scanner.Err()
-	}
-
-	// Lire la première ligne non commentée pour obtenir le numéro magique
-	if magicNumber, err = readNextLine(); err != nil {
-		return nil, err
-	}
-
 	if magicNumber != "P2" && magicNumber != "P5" {
 		return nil, errors.New("type de fichier non pris en charge")
 	}
 
-	// Lire les dimensions et la valeur maximale
-	dimensions, err := readNextLine()
+	widthStr, err := hr.readToken()
 	if err != nil {
 		return nil, err
 	}
-
-	dimTokens := strings.Fields(dimensions)
-	if len(dimTokens) != 2 {
+	heightStr, err := hr.readToken()
+	if err != nil {
+		return nil, err
+	}
+	width, err := strconv.Atoi(widthStr)
+	if err != nil {
+		return nil, errors.New("dimensions d'image invalides")
+	}
+	height, err := strconv.Atoi(heightStr)
+	if err != nil {
 		return nil, errors.New("dimensions d'image invalides")
 	}
 
-	width, _ := strconv.Atoi(dimTokens[0])
-	height, _ := strconv.Atoi(dimTokens[1])
-
-	maxValueStr, err := readNextLine()
+	maxValueStr, err := hr.readToken()
 	if err != nil {
 		return nil, err
 	}
-
 	maxValue, err := strconv.Atoi(maxValueStr)
 	if err != nil {
 		return nil, errors.New("valeur maximale invalide")
 	}
 
-	var data [][]uint8
+	var data [][]uint16
 
 	// Si l'image n'est pas vide, initialisez les données avec une slice vide
 	if width > 0 && height > 0 {
-		data = make([][]uint8, height)
+		data = make([][]uint16, height)
 		for i := range data {
-			data[i] = make([]uint8, width)
+			data[i] = make([]uint16, width)
 		}
 
 		if magicNumber == "P2" {
 			for i := 0; i < height; i++ {
-				line, err := readNextLine()
-				if err != nil {
-					return nil, err
-				}
-
-				tokens := strings.Fields(line)
-				for j, token := range tokens {
+				for j := 0; j < width; j++ {
+					token, err := hr.readToken()
+					if err != nil {
+						return nil, err
+					}
 					pixel, err := strconv.Atoi(token)
 					if err != nil {
 						return nil, err
 					}
-					data[i][j] = uint8(pixel)
+					data[i][j] = uint16(pixel)
 				}
 			}
 		} else if magicNumber == "P5" {
-			buffer := make([]byte, width*height)
-			_, err := file.Read(buffer)
-			if err != nil {
+			if err := hr.skipSingleWhitespace(); err != nil {
+				return nil, err
+			}
+
+			bytesPerSample := 1
+			if maxValue > 255 {
+				bytesPerSample = 2
+			}
+
+			buffer := make([]byte, width*height*bytesPerSample)
+			if _, err := io.ReadFull(hr.br, buffer); err != nil {
 				return nil, err
 			}
 
 			for i := 0; i < height; i++ {
 				for j := 0; j < width; j++ {
-					data[i][j] = uint8(buffer[i*width+j])
+					offset := (i*width + j) * bytesPerSample
+					if bytesPerSample == 1 {
+						data[i][j] = uint16(buffer[offset])
+					} else {
+						// 16-bit samples are big-endian per the Netpbm spec.
+						data[i][j] = uint16(buffer[offset])<<8 | uint16(buffer[offset+1])
+					}
 				}
 			}
 		}
 	}
 
 	return &PGM{
-		data:         data,
-		width:        width,
-		height:       height,
-		magicNumber:  magicNumber,
-		max:          maxValue,
+		data:        data,
+		width:       width,
+		height:      height,
+		magicNumber: magicNumber,
+		max:         maxValue,
 	}, nil
 }
 
@@ -134,16 +129,36 @@ func (pgm *PGM) Size() (int, int) {
 	return pgm.width, pgm.height
 }
 
-// At retourne la valeur du pixel à la position (x, y).
-func (pgm *PGM) At(x, y int) uint8 {
+// Get retourne la valeur brute du pixel à la position (x, y).
+func (pgm *PGM) Get(x, y int) uint16 {
 	return pgm.data[y][x]
 }
 
 // Set définit la valeur du pixel à la position (x, y).
-func (pgm *PGM) Set(x, y int, value uint8) {
+func (pgm *PGM) Set(x, y int, value uint16) {
 	pgm.data[y][x] = value
 }
 
+// ColorModel implements image.Image.
+func (pgm *PGM) ColorModel() color.Model {
+	return color.GrayModel
+}
+
+// Bounds implements image.Image.
+func (pgm *PGM) Bounds() image.Rectangle {
+	return image.Rect(0, 0, pgm.width, pgm.height)
+}
+
+// At implements image.Image, rescaling the 0..max sample to the 0-255 range
+// expected of color.Gray.
+func (pgm *PGM) At(x, y int) color.Color {
+	v := int(pgm.data[y][x])
+	if pgm.max == 0 {
+		return color.Gray{Y: uint8(v)}
+	}
+	return color.Gray{Y: uint8(v * 255 / pgm.max)}
+}
+
 // Save enregistre l'image PGM dans un fichier et retourne une erreur s'il y a un problème.
 func (pgm *PGM) Save(filename string) error {
 	file, err := os.Create(filename)
@@ -152,15 +167,50 @@ func (pgm *PGM) Save(filename string) error {
 	}
 	defer file.Close()
 
-	writer := bufio.NewWriter(file)
+	return EncodePGM(file, pgm, pgm.max)
+}
 
-	// Écrire le numéro magique, les dimensions et la valeur maximale
-	fmt.Fprintf(writer, "%s\n%d %d\n%d\n", pgm.magicNumber, pgm.width, pgm.height, pgm.max)
+// EncodePGM writes img to w in Netpbm PGM format (P2) at the given
+// maxValue. When img is a *PGM its samples are written out directly
+// (rescaled from its own max) so bit depth above 8 bits round-trips
+// intact; any other image.Image is converted via the 16-bit gray model
+// so sources like image.Gray16 don't get clipped to 8 bits first.
+func EncodePGM(w io.Writer, img image.Image, maxValue int) error {
+	if maxValue <= 0 {
+		maxValue = 255
+	}
 
-	// Écrire les données
-	for i := 0; i < pgm.height; i++ {
-		for j := 0; j < pgm.width; j++ {
-			fmt.Fprintf(writer, "%d ", pgm.data[i][j])
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	writer := bufio.NewWriter(w)
+
+	magicNumber := "P2"
+	if pgm, ok := img.(*PGM); ok {
+		if pgm.magicNumber != "" {
+			magicNumber = pgm.magicNumber
+		}
+
+		fmt.Fprintf(writer, "%s\n%d %d\n%d\n", magicNumber, width, height, maxValue)
+		srcMax := pgm.max
+		if srcMax == 0 {
+			srcMax = 255
+		}
+		for i := 0; i < pgm.height; i++ {
+			for j := 0; j < pgm.width; j++ {
+				fmt.Fprintf(writer, "%d ", int(pgm.data[i][j])*maxValue/srcMax)
+			}
+			fmt.Fprintln(writer)
+		}
+		return writer.Flush()
+	}
+
+	fmt.Fprintf(writer, "%s\n%d %d\n%d\n", magicNumber, width, height, maxValue)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray16 := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+			fmt.Fprintf(writer, "%d ", int(gray16.Y)*maxValue/65535)
 		}
 		fmt.Fprintln(writer)
 	}
@@ -172,7 +222,7 @@ func (pgm *PGM) Save(filename string) error {
 func (pgm *PGM) Invert() {
 	for i := 0; i < pgm.height; i++ {
 		for j := 0; j < pgm.width; j++ {
-			pgm.data[i][j] = uint8(pgm.max) - pgm.data[i][j]
+			pgm.data[i][j] = uint16(pgm.max) - pgm.data[i][j]
 		}
 	}
 }
@@ -201,8 +251,28 @@ func (pgm *PGM) SetMagicNumber(magicNumber string) {
 }
 
 // SetMaxValue sets the max value of the PGM image.
-func (pgm *PGM) SetMaxValue(maxValue uint8) {
+func (pgm *PGM) SetMaxValue(maxValue uint16) {
 	pgm.max = int(maxValue)
 }
 
-// Rotate
+// Max returns the max value of the PGM image.
+func (pgm *PGM) Max() int {
+	return pgm.max
+}
+
+func decodePGM(r io.Reader) (image.Image, error) {
+	return ReadPGM(r)
+}
+
+func decodeConfigPGM(r io.Reader) (image.Config, error) {
+	pgm, err := ReadPGM(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.GrayModel, Width: pgm.width, Height: pgm.height}, nil
+}
+
+func init() {
+	image.RegisterFormat("pgm", "P2", decodePGM, decodeConfigPGM)
+	image.RegisterFormat("pgm", "P5", decodePGM, decodeConfigPGM)
+}
@@ -0,0 +1,257 @@
+// Command netpbm is a small toolkit for inspecting and converting Netpbm
+// (PBM/PGM/PPM) images and the common foreign formats (PNG, JPEG, BMP).
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/bmp"
+
+	"github.com/salmanMuhomed/Netpbm"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "info":
+		err = runInfo(os.Args[2:])
+	case "convert":
+		err = runConvert(os.Args[2:])
+	case "invert":
+		err = runInvert(os.Args[2:])
+	case "flip":
+		err = runFlip(os.Args[2:])
+	case "rotate":
+		err = runRotate(os.Args[2:])
+	case "resize":
+		err = runResize(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "netpbm:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: netpbm <convert|info|invert|flip|rotate|resize> ...")
+}
+
+// loadNetpbm reads filename as a Netpbm image, sniffing its magic number,
+// and returns the concrete *netpbm.PBM, *netpbm.PGM or *netpbm.PPM.
+func loadNetpbm(filename string) (interface{}, error) {
+	return netpbm.ReadNetpbm(filename)
+}
+
+func runInfo(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: netpbm info <file>")
+	}
+
+	img, err := loadNetpbm(args[0])
+	if err != nil {
+		return err
+	}
+
+	switch v := img.(type) {
+	case *netpbm.PBM:
+		w, h := v.Size()
+		fmt.Printf("PBM %dx%d\n", w, h)
+	case *netpbm.PGM:
+		w, h := v.Size()
+		fmt.Printf("PGM %dx%d\n", w, h)
+	case *netpbm.PPM:
+		w, h := v.Size()
+		fmt.Printf("PPM %dx%d\n", w, h)
+	}
+	return nil
+}
+
+func runInvert(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: netpbm invert <in> <out>")
+	}
+
+	img, err := loadNetpbm(args[0])
+	if err != nil {
+		return err
+	}
+
+	switch v := img.(type) {
+	case *netpbm.PBM:
+		v.Invert()
+		return v.Save(args[1])
+	case *netpbm.PGM:
+		v.Invert()
+		return v.Save(args[1])
+	case *netpbm.PPM:
+		v.Invert()
+		return v.Save(args[1])
+	}
+	return fmt.Errorf("unsupported image type")
+}
+
+func runFlip(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: netpbm flip <in> <out>")
+	}
+
+	img, err := loadNetpbm(args[0])
+	if err != nil {
+		return err
+	}
+
+	switch v := img.(type) {
+	case *netpbm.PBM:
+		v.Flip()
+		return v.Save(args[1])
+	case *netpbm.PGM:
+		v.Flip()
+		return v.Save(args[1])
+	case *netpbm.PPM:
+		v.Flip()
+		return v.Save(args[1])
+	}
+	return fmt.Errorf("unsupported image type")
+}
+
+func runRotate(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("usage: netpbm rotate <in> <out> <angle>")
+	}
+
+	angle, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return fmt.Errorf("invalid angle %q: %w", args[2], err)
+	}
+
+	img, err := loadNetpbm(args[0])
+	if err != nil {
+		return err
+	}
+
+	switch v := img.(type) {
+	case *netpbm.PBM:
+		return v.Rotate(angle, false).Save(args[1])
+	case *netpbm.PGM:
+		return v.Rotate(angle, 0).Save(args[1])
+	case *netpbm.PPM:
+		return v.Rotate(angle, color.RGBA64{A: 0xffff}).Save(args[1])
+	}
+	return fmt.Errorf("unsupported image type")
+}
+
+func runResize(args []string) error {
+	if len(args) != 4 {
+		return fmt.Errorf("usage: netpbm resize <in> <out> <width> <height>")
+	}
+
+	newW, err := strconv.Atoi(args[2])
+	if err != nil {
+		return fmt.Errorf("invalid width %q: %w", args[2], err)
+	}
+	newH, err := strconv.Atoi(args[3])
+	if err != nil {
+		return fmt.Errorf("invalid height %q: %w", args[3], err)
+	}
+	if newW <= 0 || newH <= 0 {
+		return fmt.Errorf("invalid size %dx%d: width and height must be positive", newW, newH)
+	}
+
+	img, err := loadNetpbm(args[0])
+	if err != nil {
+		return err
+	}
+
+	switch v := img.(type) {
+	case *netpbm.PBM:
+		return v.Resize(newW, newH, netpbm.Bilinear).Save(args[1])
+	case *netpbm.PGM:
+		return v.Resize(newW, newH, netpbm.Bilinear).Save(args[1])
+	case *netpbm.PPM:
+		return v.Resize(newW, newH, netpbm.Bilinear).Save(args[1])
+	}
+	return fmt.Errorf("unsupported image type")
+}
+
+// runConvert decodes in with the standard image package (Netpbm formats are
+// registered by the netpbm package's init funcs, same as PNG/JPEG/BMP) and
+// re-encodes it to out, picking the output format from out's extension.
+func runConvert(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: netpbm convert <in> <out>")
+	}
+
+	in, out := args[0], args[1]
+
+	src, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	img, _, err := image.Decode(src)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", in, err)
+	}
+
+	dst, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	switch strings.ToLower(filepath.Ext(out)) {
+	case ".pbm":
+		return netpbm.EncodePBM(dst, img)
+	case ".pgm":
+		maxValue := 255
+		if pgm, ok := img.(*netpbm.PGM); ok {
+			maxValue = pgm.Max()
+		}
+		return netpbm.EncodePGM(dst, img, maxValue)
+	case ".ppm":
+		maxValue := 255
+		if ppm, ok := img.(*netpbm.PPM); ok {
+			maxValue = ppm.Max()
+		}
+		return netpbm.EncodePPM(dst, img, maxValue)
+	case ".png":
+		// Go through the type-specific EncodePNG for our own formats so
+		// bit depth above 8 bits (PGM/PPM with max > 255) is preserved
+		// instead of being silently downscaled by the generic png.Encode
+		// path, which doesn't know about image.Image's color model here.
+		switch v := img.(type) {
+		case *netpbm.PBM:
+			return v.EncodePNG(dst)
+		case *netpbm.PGM:
+			return v.EncodePNG(dst)
+		case *netpbm.PPM:
+			return v.EncodePNG(dst)
+		default:
+			return png.Encode(dst, img)
+		}
+	case ".jpg", ".jpeg":
+		return jpeg.Encode(dst, img, nil)
+	case ".bmp":
+		return bmp.Encode(dst, img)
+	default:
+		return fmt.Errorf("unrecognized output extension %q", filepath.Ext(out))
+	}
+}
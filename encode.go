@@ -0,0 +1,120 @@
+package netpbm
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+
+	"golang.org/x/image/bmp"
+)
+
+// asGray16Image converts pgm to an *image.Gray16 so png.Encode emits a
+// 16-bit grayscale PNG instead of clipping to 8 bits when max > 255.
+func (pgm *PGM) asGray16Image() *image.Gray16 {
+	maxVal := pgm.max
+	if maxVal == 0 {
+		maxVal = 255
+	}
+
+	img := image.NewGray16(image.Rect(0, 0, pgm.width, pgm.height))
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			v := uint16(int(pgm.data[y][x]) * 65535 / maxVal)
+			img.SetGray16(x, y, color.Gray16{Y: v})
+		}
+	}
+	return img
+}
+
+// EncodePNG writes pbm to w as a PNG image.
+func (pbm *PBM) EncodePNG(w io.Writer) error {
+	return png.Encode(w, pbm)
+}
+
+// EncodeJPEG writes pbm to w as a JPEG image with the given quality (1-100).
+func (pbm *PBM) EncodeJPEG(w io.Writer, quality int) error {
+	return jpeg.Encode(w, pbm, &jpeg.Options{Quality: quality})
+}
+
+// EncodeBMP writes pbm to w as a BMP image.
+func (pbm *PBM) EncodeBMP(w io.Writer) error {
+	return bmp.Encode(w, pbm)
+}
+
+// EncodePNG writes pgm to w as a PNG image, preserving 16-bit depth when
+// pgm.max > 255 instead of silently downscaling to 8 bits.
+func (pgm *PGM) EncodePNG(w io.Writer) error {
+	if pgm.max > 255 {
+		return png.Encode(w, pgm.asGray16Image())
+	}
+	return png.Encode(w, pgm)
+}
+
+// EncodeJPEG writes pgm to w as a JPEG image with the given quality (1-100).
+// JPEG has no 16-bit grayscale mode, so samples are always written as 8-bit.
+func (pgm *PGM) EncodeJPEG(w io.Writer, quality int) error {
+	return jpeg.Encode(w, pgm, &jpeg.Options{Quality: quality})
+}
+
+// EncodeBMP writes pgm to w as a BMP image. BMP has no 16-bit grayscale
+// mode, so samples are always written as 8-bit.
+func (pgm *PGM) EncodeBMP(w io.Writer) error {
+	return bmp.Encode(w, pgm)
+}
+
+// asRGBA64Image converts ppm to an *image.RGBA64 so png.Encode emits a
+// 16-bit-per-channel PNG instead of clipping to 8 bits when max > 255.
+func (ppm *PPM) asRGBA64Image() *image.RGBA64 {
+	maxVal := ppm.max
+	if maxVal == 0 {
+		maxVal = 255
+	}
+
+	img := image.NewRGBA64(image.Rect(0, 0, ppm.width, ppm.height))
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			p := ppm.data[y][x]
+			img.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(int(p.R) * 65535 / maxVal),
+				G: uint16(int(p.G) * 65535 / maxVal),
+				B: uint16(int(p.B) * 65535 / maxVal),
+				A: 0xffff,
+			})
+		}
+	}
+	return img
+}
+
+// EncodePNG writes ppm to w as a PNG image, preserving 16-bit depth when
+// ppm.max > 255 instead of silently downscaling to 8 bits.
+func (ppm *PPM) EncodePNG(w io.Writer) error {
+	if ppm.max > 255 {
+		return png.Encode(w, ppm.asRGBA64Image())
+	}
+	return png.Encode(w, ppm)
+}
+
+// EncodeJPEG writes ppm to w as a JPEG image with the given quality (1-100).
+func (ppm *PPM) EncodeJPEG(w io.Writer, quality int) error {
+	return jpeg.Encode(w, ppm, &jpeg.Options{Quality: quality})
+}
+
+// EncodeBMP writes ppm to w as a BMP image.
+func (ppm *PPM) EncodeBMP(w io.Writer) error {
+	return bmp.Encode(w, ppm)
+}
+
+// DecodeForeign opens filename and decodes it with the standard image
+// package, for foreign formats (PNG, JPEG, BMP, ...) that aren't Netpbm.
+func DecodeForeign(filename string) (image.Image, string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	return image.Decode(file)
+}
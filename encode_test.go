@@ -0,0 +1,56 @@
+package netpbm
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// TestPGMEncodePNGPreservesBitDepth checks that a PGM with max > 255 is
+// written out as a 16-bit grayscale PNG instead of being clipped to 8 bits,
+// the conversion path the backlog asked for.
+func TestPGMEncodePNGPreservesBitDepth(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader([]byte("P2\n1 1\n65535\n65535\n")))
+	pgm, err := ReadPGM(r)
+	if err != nil {
+		t.Fatalf("ReadPGM: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pgm.EncodePNG(&buf); err != nil {
+		t.Fatalf("EncodePNG: %v", err)
+	}
+
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+	if _, ok := decoded.(*image.Gray16); !ok {
+		t.Fatalf("decoded PNG type = %T, want *image.Gray16", decoded)
+	}
+}
+
+// TestPPMEncodePNGPreservesBitDepth checks the same for PPM, which should
+// round-trip through a 16-bit-per-channel RGBA64 PNG rather than clipping.
+func TestPPMEncodePNGPreservesBitDepth(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader([]byte("P3\n1 1\n65535\n65535 0 0\n")))
+	ppm, err := ReadPPM(r)
+	if err != nil {
+		t.Fatalf("ReadPPM: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ppm.EncodePNG(&buf); err != nil {
+		t.Fatalf("EncodePNG: %v", err)
+	}
+
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+	if _, ok := decoded.(*image.RGBA64); !ok {
+		t.Fatalf("decoded PNG type = %T, want *image.RGBA64", decoded)
+	}
+}
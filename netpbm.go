@@ -0,0 +1,65 @@
+// Package netpbm implements readers, writers and basic image operations for
+// the Netpbm family of formats (PBM, PGM, PPM), plugging them into the
+// standard image package the same way golang.org/x/image/bmp and
+// golang.org/x/image/tiff do for their formats.
+//
+// Each format's init() calls image.RegisterFormat for its magic numbers, so
+// image.Decode(r) already dispatches to ReadPBM/ReadPGM/ReadPPM without a
+// package-level Decode of our own; ReadNetpbm and the per-type ReadPBM/
+// ReadPGM/ReadPPM are the entry points for callers who want the concrete
+// type back instead of a bare image.Image.
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"os"
+)
+
+// Image is satisfied by every Netpbm image type (PBM, PGM, PPM). It composes
+// image.Image so decoded values can be handed straight to image/png,
+// image/jpeg or any other standard encoder.
+type Image interface {
+	image.Image
+	Size() (int, int)
+}
+
+// ReadNetpbm opens filename, sniffs its magic number and dispatches to
+// ReadPBM, ReadPGM or ReadPPM, returning the concrete *PBM, *PGM or *PPM.
+func ReadNetpbm(filename string) (Image, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	br := bufio.NewReader(file)
+	magic, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	switch string(magic) {
+	case "P1", "P4":
+		return ReadPBM(br)
+	case "P2", "P5":
+		return ReadPGM(br)
+	case "P3", "P6":
+		return ReadPPM(br)
+	default:
+		return nil, fmt.Errorf("unsupported netpbm magic number %q", magic)
+	}
+}
+
+// ResampleFilter selects the interpolation kernel used by Resize.
+type ResampleFilter int
+
+const (
+	// NearestNeighbor picks the closest source pixel; fastest, blockiest.
+	NearestNeighbor ResampleFilter = iota
+	// Bilinear interpolates linearly between the four nearest source pixels.
+	Bilinear
+	// Lanczos3 uses a windowed sinc kernel with a radius of 3 source pixels.
+	Lanczos3
+)
@@ -0,0 +1,66 @@
+package netpbm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestReadNetpbmDispatch checks that ReadNetpbm sniffs the magic number and
+// dispatches to the right concrete type for each of the six Netpbm formats.
+func TestReadNetpbmDispatch(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"pbm-ascii", "P1\n1 1\n1\n", "*netpbm.PBM"},
+		{"pbm-binary", "P4\n8 1\n\x80", "*netpbm.PBM"},
+		{"pgm-ascii", "P2\n1 1\n255\n10\n", "*netpbm.PGM"},
+		{"pgm-binary", "P5\n1 1\n255\n\x0a", "*netpbm.PGM"},
+		{"ppm-ascii", "P3\n1 1\n255\n10 20 30\n", "*netpbm.PPM"},
+		{"ppm-binary", "P6\n1 1\n255\n\x0a\x14\x1e", "*netpbm.PPM"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.name)
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			img, err := ReadNetpbm(path)
+			if err != nil {
+				t.Fatalf("ReadNetpbm: %v", err)
+			}
+
+			var got string
+			switch img.(type) {
+			case *PBM:
+				got = "*netpbm.PBM"
+			case *PGM:
+				got = "*netpbm.PGM"
+			case *PPM:
+				got = "*netpbm.PPM"
+			default:
+				got = "unknown"
+			}
+			if got != tc.want {
+				t.Fatalf("ReadNetpbm(%q) dispatched to %s, want %s", tc.name, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestReadNetpbmUnsupportedMagic checks that an unrecognized magic number is
+// reported as an error rather than silently misdispatched.
+func TestReadNetpbmUnsupportedMagic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-netpbm")
+	if err := os.WriteFile(path, []byte("XX\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := ReadNetpbm(path); err == nil {
+		t.Fatal("ReadNetpbm with unsupported magic number: want error, got nil")
+	}
+}
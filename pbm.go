@@ -1,65 +1,58 @@
-package main
+package netpbm
 
 import (
 	"bufio"
 	"errors"
 	"fmt"
+	"image"
+	"image/color"
+	"io"
 	"os"
 	"strconv"
-	"strings"
 )
 
 type PBM struct {
-	data         [][]bool
+	data          [][]bool
 	width, height int
-	magicNumber  string
+	magicNumber   string
 }
 
-// ReadPBM reads a PBM image from a file and returns a struct that represents the image.
-func ReadPBM(filename string) (*PBM, error) {
-	file, err := os.Open(filename)
+// ReadPBM reads a single PBM image (P1 or P4) from r and returns a struct
+// that represents the image. r is read through a shared headerReader so
+// the header tokens and the raw P4 raster come from the exact same
+// buffered stream, and r need not be seekable (HTTP bodies, stdin, ...).
+// When r already has ReadByte/UnreadByte (e.g. a *bufio.Reader), it is used
+// as is, so callers can chain ReadPBM/ReadPGM/ReadPPM calls over one r to
+// read concatenated multi-image Netpbm streams. Any other r is wrapped in a
+// new bufio.Reader for each call, which may read past the end of the image.
+func ReadPBM(r io.Reader) (*PBM, error) {
+	hr := &headerReader{br: asByteReader(r)}
+
+	magicNumber, err := hr.readToken()
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	var magicNumber string
-
-	// Function to read the next non-commented line
-	readNextLine := func() (string, error) {
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			// Ignore empty lines or lines starting with "#"
-			if line != "" && !strings.HasPrefix(line, "#") {
-				return line, nil
-			}
-		}
-		return "", scanner.Err()
-	}
-
-	// Read the first non-commented line to get the magic number
-	if magicNumber, err = readNextLine(); err != nil {
-		return nil, err
-	}
-
 	if magicNumber != "P1" && magicNumber != "P4" {
 		return nil, errors.New("unsupported file type")
 	}
 
-	// Read the dimensions
-	dimensions, err := readNextLine()
+	widthStr, err := hr.readToken()
+	if err != nil {
+		return nil, err
+	}
+	heightStr, err := hr.readToken()
 	if err != nil {
 		return nil, err
 	}
 
-	dimTokens := strings.Fields(dimensions)
-	if len(dimTokens) != 2 {
+	width, err := strconv.Atoi(widthStr)
+	if err != nil {
+		return nil, errors.New("invalid image dimensions")
+	}
+	height, err := strconv.Atoi(heightStr)
+	if err != nil {
 		return nil, errors.New("invalid image dimensions")
 	}
-
-	width, _ := strconv.Atoi(dimTokens[0])
-	height, _ := strconv.Atoi(dimTokens[1])
 
 	var data [][]bool
 
@@ -72,13 +65,11 @@ func ReadPBM(filename string) (*PBM, error) {
 
 		if magicNumber == "P1" {
 			for i := 0; i < height; i++ {
-				line, err := readNextLine()
-				if err != nil {
-					return nil, err
-				}
-
-				tokens := strings.Fields(line)
-				for j, token := range tokens {
+				for j := 0; j < width; j++ {
+					token, err := hr.readToken()
+					if err != nil {
+						return nil, err
+					}
 					pixel, err := strconv.Atoi(token)
 					if err != nil {
 						return nil, err
@@ -87,6 +78,10 @@ func ReadPBM(filename string) (*PBM, error) {
 				}
 			}
 		} else if magicNumber == "P4" {
+			if err := hr.skipSingleWhitespace(); err != nil {
+				return nil, err
+			}
+
 			// Calculate the number of padding bits
 			paddingBits := (8 - width%8) % 8
 
@@ -96,8 +91,7 @@ func ReadPBM(filename string) (*PBM, error) {
 			// Create a buffer to read binary data
 			buffer := make([]byte, bytesPerRow)
 			for i := 0; i < height; i++ {
-				_, err := file.Read(buffer)
-				if err != nil {
+				if _, err := io.ReadFull(hr.br, buffer); err != nil {
 					return nil, err
 				}
 
@@ -126,8 +120,8 @@ func (pbm *PBM) Size() (int, int) {
 	return pbm.width, pbm.height
 }
 
-// At returns the value of the pixel at (x, y).
-func (pbm *PBM) At(x, y int) bool {
+// Get returns the raw boolean value of the pixel at (x, y).
+func (pbm *PBM) Get(x, y int) bool {
 	return pbm.data[y][x]
 }
 
@@ -136,6 +130,25 @@ func (pbm *PBM) Set(x, y int, value bool) {
 	pbm.data[y][x] = value
 }
 
+// ColorModel implements image.Image. PBM only ever holds black and white
+// pixels, so the gray model is enough.
+func (pbm *PBM) ColorModel() color.Model {
+	return color.GrayModel
+}
+
+// Bounds implements image.Image.
+func (pbm *PBM) Bounds() image.Rectangle {
+	return image.Rect(0, 0, pbm.width, pbm.height)
+}
+
+// At implements image.Image. Per the Netpbm spec a set bit is black.
+func (pbm *PBM) At(x, y int) color.Color {
+	if pbm.data[y][x] {
+		return color.Gray{Y: 0}
+	}
+	return color.Gray{Y: 255}
+}
+
 // Save saves the PBM image to a file and returns an error if there was a problem.
 func (pbm *PBM) Save(filename string) error {
 	file, err := os.Create(filename)
@@ -144,15 +157,32 @@ func (pbm *PBM) Save(filename string) error {
 	}
 	defer file.Close()
 
-	writer := bufio.NewWriter(file)
+	return EncodePBM(file, pbm)
+}
 
-	// Write magic number and dimensions
-	fmt.Fprintf(writer, "%s\n%d %d\n", pbm.magicNumber, pbm.width, pbm.height)
+// EncodePBM writes img to w in Netpbm PBM format (P1), converting any
+// image.Image to black-and-white along the way.
+func EncodePBM(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
 
-	// Write data
-	for i := 0; i < pbm.height; i++ {
-		for j := 0; j < pbm.width; j++ {
-			fmt.Fprintf(writer, "%d ", map[bool]int{false: 0, true: 1}[pbm.data[i][j]])
+	writer := bufio.NewWriter(w)
+
+	magicNumber := "P1"
+	if pbm, ok := img.(*PBM); ok && pbm.magicNumber != "" {
+		magicNumber = pbm.magicNumber
+	}
+
+	fmt.Fprintf(writer, "%s\n%d %d\n", magicNumber, width, height)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+			bit := 0
+			if gray.Y < 128 {
+				bit = 1
+			}
+			fmt.Fprintf(writer, "%d ", bit)
 		}
 		fmt.Fprintln(writer)
 	}
@@ -192,21 +222,19 @@ func (pbm *PBM) SetMagicNumber(magicNumber string) {
 	pbm.magicNumber = magicNumber
 }
 
-func main() {
-	// Example usage
-	pbm, err := ReadPBM("P1.txt")
+func decodePBM(r io.Reader) (image.Image, error) {
+	return ReadPBM(r)
+}
+
+func decodeConfigPBM(r io.Reader) (image.Config, error) {
+	pbm, err := ReadPBM(r)
 	if err != nil {
-		fmt.Println("Error reading file:", err)
-		return
+		return image.Config{}, err
 	}
+	return image.Config{ColorModel: color.GrayModel, Width: pbm.width, Height: pbm.height}, nil
+}
 
-	width, height := pbm.Size()
-	fmt.Printf("Image size: %d x %d\n", width, height)
-
-	fmt.Println("Original Image:")
-	for i := 0; i < height; i++ {
-		for j := 0; j < width; j++ {
-			fmt.Print(pbm.At(j, i), " ")
-		}
-	}
+func init() {
+	image.RegisterFormat("pbm", "P1", decodePBM, decodeConfigPBM)
+	image.RegisterFormat("pbm", "P4", decodePBM, decodeConfigPBM)
 }
@@ -0,0 +1,306 @@
+package netpbm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+)
+
+// PPM représente une image PPM (couleur).
+//
+// Samples are kept raw (0..max, up to 16 bits per the Netpbm spec) rather
+// than rescaled to 8-bit on read, the same way PGM keeps raw uint16
+// samples plus max; they're only rescaled at the image.Image/encode
+// boundary (At, EncodePPM, EncodePNG, ...).
+type PPM struct {
+	data          [][]color.RGBA64
+	width, height int
+	magicNumber   string
+	max           int
+}
+
+// ReadPPM lit une image PPM (P3 ou P6) depuis r et retourne une structure
+// représentant l'image. r est lu via un headerReader partagé afin que
+// l'en-tête et le raster P6 proviennent exactement du même flux bufferisé
+// (voir ReadPBM). Plusieurs lectures ne s'enchaînent sur un même r, pour des
+// flux Netpbm concaténés, que si r a ReadByte/UnreadByte (un *bufio.Reader
+// par exemple) ; tout autre r est enveloppé dans un nouveau bufio.Reader à
+// chaque appel.
+func ReadPPM(r io.Reader) (*PPM, error) {
+	hr := &headerReader{br: asByteReader(r)}
+
+	magicNumber, err := hr.readToken()
+	if err != nil {
+		return nil, err
+	}
+	if magicNumber != "P3" && magicNumber != "P6" {
+		return nil, errors.New("type de fichier non pris en charge")
+	}
+
+	widthStr, err := hr.readToken()
+	if err != nil {
+		return nil, err
+	}
+	heightStr, err := hr.readToken()
+	if err != nil {
+		return nil, err
+	}
+	width, err := strconv.Atoi(widthStr)
+	if err != nil {
+		return nil, errors.New("dimensions d'image invalides")
+	}
+	height, err := strconv.Atoi(heightStr)
+	if err != nil {
+		return nil, errors.New("dimensions d'image invalides")
+	}
+
+	maxValueStr, err := hr.readToken()
+	if err != nil {
+		return nil, err
+	}
+	maxValue, err := strconv.Atoi(maxValueStr)
+	if err != nil {
+		return nil, errors.New("valeur maximale invalide")
+	}
+
+	var data [][]color.RGBA64
+
+	if width > 0 && height > 0 {
+		data = make([][]color.RGBA64, height)
+		for i := range data {
+			data[i] = make([]color.RGBA64, width)
+		}
+
+		if magicNumber == "P3" {
+			for i := 0; i < height; i++ {
+				for j := 0; j < width; j++ {
+					rTok, err := hr.readToken()
+					if err != nil {
+						return nil, err
+					}
+					gTok, err := hr.readToken()
+					if err != nil {
+						return nil, err
+					}
+					bTok, err := hr.readToken()
+					if err != nil {
+						return nil, err
+					}
+					r, _ := strconv.Atoi(rTok)
+					g, _ := strconv.Atoi(gTok)
+					b, _ := strconv.Atoi(bTok)
+					data[i][j] = color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: 0xffff}
+				}
+			}
+		} else if magicNumber == "P6" {
+			if err := hr.skipSingleWhitespace(); err != nil {
+				return nil, err
+			}
+
+			bytesPerSample := 1
+			if maxValue > 255 {
+				bytesPerSample = 2
+			}
+			buffer := make([]byte, width*height*3*bytesPerSample)
+			if _, err := io.ReadFull(hr.br, buffer); err != nil {
+				return nil, err
+			}
+
+			readSample := func(offset int) uint16 {
+				if bytesPerSample == 1 {
+					return uint16(buffer[offset])
+				}
+				// 16-bit samples are big-endian per the Netpbm spec.
+				return uint16(buffer[offset])<<8 | uint16(buffer[offset+1])
+			}
+
+			for i := 0; i < height; i++ {
+				for j := 0; j < width; j++ {
+					base := (i*width + j) * 3 * bytesPerSample
+					r := readSample(base)
+					g := readSample(base + bytesPerSample)
+					b := readSample(base + 2*bytesPerSample)
+					data[i][j] = color.RGBA64{R: r, G: g, B: b, A: 0xffff}
+				}
+			}
+		}
+	}
+
+	return &PPM{
+		data:        data,
+		width:       width,
+		height:      height,
+		magicNumber: magicNumber,
+		max:         maxValue,
+	}, nil
+}
+
+// Size retourne la largeur et la hauteur de l'image.
+func (ppm *PPM) Size() (int, int) {
+	return ppm.width, ppm.height
+}
+
+// Get retourne la couleur brute (0..max) du pixel à la position (x, y).
+func (ppm *PPM) Get(x, y int) color.RGBA64 {
+	return ppm.data[y][x]
+}
+
+// Set définit la couleur brute (0..max) du pixel à la position (x, y).
+func (ppm *PPM) Set(x, y int, value color.RGBA64) {
+	ppm.data[y][x] = value
+}
+
+// ColorModel implements image.Image.
+func (ppm *PPM) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+// Bounds implements image.Image.
+func (ppm *PPM) Bounds() image.Rectangle {
+	return image.Rect(0, 0, ppm.width, ppm.height)
+}
+
+// At implements image.Image, scaling the raw 0..max sample up to the
+// 0-65535 range expected of color.RGBA64 when max isn't already 65535.
+func (ppm *PPM) At(x, y int) color.Color {
+	maxVal := ppm.max
+	if maxVal == 0 {
+		maxVal = 255
+	}
+	p := ppm.data[y][x]
+	if maxVal == 0xffff {
+		return p
+	}
+	return color.RGBA64{
+		R: uint16(int(p.R) * 0xffff / maxVal),
+		G: uint16(int(p.G) * 0xffff / maxVal),
+		B: uint16(int(p.B) * 0xffff / maxVal),
+		A: 0xffff,
+	}
+}
+
+// Save enregistre l'image PPM dans un fichier et retourne une erreur s'il y a un problème.
+func (ppm *PPM) Save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return EncodePPM(file, ppm, ppm.max)
+}
+
+// EncodePPM writes img to w in Netpbm PPM format (P3) at the given
+// maxValue. When img is a *PPM its raw samples are rescaled from its own
+// max, preserving bit depth above 8 bits; any other image.Image is
+// converted via the 16-bit RGBA model so sources like image.RGBA64 don't
+// get clipped to 8 bits first.
+func EncodePPM(w io.Writer, img image.Image, maxValue int) error {
+	if maxValue <= 0 {
+		maxValue = 255
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	writer := bufio.NewWriter(w)
+
+	magicNumber := "P3"
+	if ppm, ok := img.(*PPM); ok {
+		if ppm.magicNumber != "" {
+			magicNumber = ppm.magicNumber
+		}
+
+		fmt.Fprintf(writer, "%s\n%d %d\n%d\n", magicNumber, width, height, maxValue)
+		srcMax := ppm.max
+		if srcMax == 0 {
+			srcMax = 255
+		}
+		for i := 0; i < ppm.height; i++ {
+			for j := 0; j < ppm.width; j++ {
+				p := ppm.data[i][j]
+				fmt.Fprintf(writer, "%d %d %d ", int(p.R)*maxValue/srcMax, int(p.G)*maxValue/srcMax, int(p.B)*maxValue/srcMax)
+			}
+			fmt.Fprintln(writer)
+		}
+		return writer.Flush()
+	}
+
+	fmt.Fprintf(writer, "%s\n%d %d\n%d\n", magicNumber, width, height, maxValue)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			rgba64 := color.RGBA64Model.Convert(img.At(x, y)).(color.RGBA64)
+			fmt.Fprintf(writer, "%d %d %d ", int(rgba64.R)*maxValue/0xffff, int(rgba64.G)*maxValue/0xffff, int(rgba64.B)*maxValue/0xffff)
+		}
+		fmt.Fprintln(writer)
+	}
+
+	return writer.Flush()
+}
+
+// Invert inverts the colors of the PPM image.
+func (ppm *PPM) Invert() {
+	max := uint16(ppm.max)
+	for i := 0; i < ppm.height; i++ {
+		for j := 0; j < ppm.width; j++ {
+			p := ppm.data[i][j]
+			ppm.data[i][j] = color.RGBA64{R: max - p.R, G: max - p.G, B: max - p.B, A: p.A}
+		}
+	}
+}
+
+// Flip flips the PPM image horizontally.
+func (ppm *PPM) Flip() {
+	for i := 0; i < ppm.height; i++ {
+		for j := 0; j < ppm.width/2; j++ {
+			ppm.data[i][j], ppm.data[i][ppm.width-j-1] = ppm.data[i][ppm.width-j-1], ppm.data[i][j]
+		}
+	}
+}
+
+// Flop flops the PPM image vertically.
+func (ppm *PPM) Flop() {
+	for i := 0; i < ppm.height/2; i++ {
+		for j := 0; j < ppm.width; j++ {
+			ppm.data[i][j], ppm.data[ppm.height-i-1][j] = ppm.data[ppm.height-i-1][j], ppm.data[i][j]
+		}
+	}
+}
+
+// SetMagicNumber sets the magic number of the PPM image.
+func (ppm *PPM) SetMagicNumber(magicNumber string) {
+	ppm.magicNumber = magicNumber
+}
+
+// SetMaxValue sets the max value of the PPM image.
+func (ppm *PPM) SetMaxValue(maxValue int) {
+	ppm.max = maxValue
+}
+
+// Max returns the max value of the PPM image.
+func (ppm *PPM) Max() int {
+	return ppm.max
+}
+
+func decodePPM(r io.Reader) (image.Image, error) {
+	return ReadPPM(r)
+}
+
+func decodeConfigPPM(r io.Reader) (image.Config, error) {
+	ppm, err := ReadPPM(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.RGBA64Model, Width: ppm.width, Height: ppm.height}, nil
+}
+
+func init() {
+	image.RegisterFormat("ppm", "P3", decodePPM, decodeConfigPPM)
+	image.RegisterFormat("ppm", "P6", decodePPM, decodeConfigPPM)
+}
@@ -0,0 +1,123 @@
+package netpbm
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// asByteReader adapts r to byteReader, reusing it directly when it already
+// is one (e.g. a *bufio.Reader passed in by ReadNetpbm) instead of wrapping
+// it a second time and losing whatever it has already buffered.
+func asByteReader(r io.Reader) byteReader {
+	if br, ok := r.(byteReader); ok {
+		return br
+	}
+	return bufio.NewReader(r)
+}
+
+// headerReader tokenizes the whitespace-delimited Netpbm header (magic
+// number, width, height, maxval) from a single underlying bufio.Reader,
+// honoring "#"-to-end-of-line comments wherever they appear, then lets
+// callers fall through to reading raw pixel bytes from that same reader.
+// Using one reader for both the header and the raster data is what makes
+// this safe on non-seekable streams (HTTP bodies, stdin, ...) and on
+// concatenated multi-image streams: nothing is buffered and discarded
+// between the two phases.
+type headerReader struct {
+	br byteReader
+}
+
+// byteReader is the subset of *bufio.Reader that headerReader needs. Kept
+// as an interface so callers can pass in any reader that already satisfies
+// it (e.g. a *bufio.Reader they're sharing with other code) and so raw
+// pixel reads via io.ReadFull see the exact same buffer.
+type byteReader interface {
+	io.Reader
+	ReadByte() (byte, error)
+	UnreadByte() error
+}
+
+func isNetpbmSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// readToken returns the next whitespace-delimited header token, skipping
+// any "#" comments (which run to the next newline) encountered along the
+// way, including ones that interrupt what would otherwise be a single
+// token.
+func (hr *headerReader) readToken() (string, error) {
+	var b byte
+	var err error
+
+	// Skip leading whitespace and comment lines.
+	for {
+		b, err = hr.br.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if b == '#' {
+			if err := hr.skipComment(); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if isNetpbmSpace(b) {
+			continue
+		}
+		break
+	}
+
+	var sb strings.Builder
+	sb.WriteByte(b)
+
+	for {
+		b, err = hr.br.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return sb.String(), nil
+			}
+			return "", err
+		}
+		if b == '#' {
+			if err := hr.skipComment(); err != nil {
+				return "", err
+			}
+			break
+		}
+		if isNetpbmSpace(b) {
+			if err := hr.br.UnreadByte(); err != nil {
+				return "", err
+			}
+			break
+		}
+		sb.WriteByte(b)
+	}
+
+	return sb.String(), nil
+}
+
+func (hr *headerReader) skipComment() error {
+	for {
+		b, err := hr.br.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == '\n' {
+			return nil
+		}
+	}
+}
+
+// skipSingleWhitespace consumes exactly the one whitespace byte the Netpbm
+// spec requires between the last header token and the raster data.
+func (hr *headerReader) skipSingleWhitespace() error {
+	b, err := hr.br.ReadByte()
+	if err != nil {
+		return err
+	}
+	if !isNetpbmSpace(b) {
+		return hr.br.UnreadByte()
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package netpbm
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+// TestReadPBMConcatenatedStream checks that two PBM images back to back in
+// one stream (explicitly allowed by the Netpbm spec) can both be read off
+// a single shared reader, one ReadPBM call per image.
+func TestReadPBMConcatenatedStream(t *testing.T) {
+	const stream = "P1\n2 2\n1 0\n0 1\nP1\n1 1\n1\n"
+	r := bufio.NewReader(bytes.NewReader([]byte(stream)))
+
+	first, err := ReadPBM(r)
+	if err != nil {
+		t.Fatalf("first ReadPBM: %v", err)
+	}
+	if w, h := first.Size(); w != 2 || h != 2 {
+		t.Fatalf("first image size = %dx%d, want 2x2", w, h)
+	}
+	if !first.Get(0, 0) || first.Get(1, 0) || first.Get(0, 1) || !first.Get(1, 1) {
+		t.Fatalf("first image data = %v, want [[true false] [false true]]", first.data)
+	}
+
+	second, err := ReadPBM(r)
+	if err != nil {
+		t.Fatalf("second ReadPBM: %v", err)
+	}
+	if w, h := second.Size(); w != 1 || h != 1 {
+		t.Fatalf("second image size = %dx%d, want 1x1", w, h)
+	}
+	if !second.Get(0, 0) {
+		t.Fatalf("second image pixel = false, want true")
+	}
+}
+
+// TestReadPGMConcatenatedStream checks the same for two ASCII (P2) PGM
+// images, including a comment sitting inside the header of the second one.
+func TestReadPGMConcatenatedStream(t *testing.T) {
+	const stream = "P2\n2 1\n255\n10 20\nP2\n# a comment between header tokens\n1 1\n100\n50\n"
+	r := bufio.NewReader(bytes.NewReader([]byte(stream)))
+
+	first, err := ReadPGM(r)
+	if err != nil {
+		t.Fatalf("first ReadPGM: %v", err)
+	}
+	if first.Get(0, 0) != 10 || first.Get(1, 0) != 20 {
+		t.Fatalf("first image data = %v, want [10 20]", first.data)
+	}
+
+	second, err := ReadPGM(r)
+	if err != nil {
+		t.Fatalf("second ReadPGM: %v", err)
+	}
+	if w, h := second.Size(); w != 1 || h != 1 {
+		t.Fatalf("second image size = %dx%d, want 1x1", w, h)
+	}
+	if second.Get(0, 0) != 50 {
+		t.Fatalf("second image pixel = %d, want 50", second.Get(0, 0))
+	}
+}
+
+// TestReadPPMBinaryAfterComment checks that a "#" comment inside a P6
+// header (between height and maxval) doesn't shift where the raster data
+// starts.
+func TestReadPPMBinaryAfterComment(t *testing.T) {
+	header := "P6\n2 1 # width height\n255\n"
+	raster := []byte{255, 0, 0, 0, 255, 0}
+	r := bufio.NewReader(bytes.NewReader(append([]byte(header), raster...)))
+
+	ppm, err := ReadPPM(r)
+	if err != nil {
+		t.Fatalf("ReadPPM: %v", err)
+	}
+	if w, h := ppm.Size(); w != 2 || h != 1 {
+		t.Fatalf("size = %dx%d, want 2x1", w, h)
+	}
+	red := ppm.Get(0, 0)
+	green := ppm.Get(1, 0)
+	if red.R != 255 || red.G != 0 || red.B != 0 {
+		t.Fatalf("pixel(0,0) = %v, want red", red)
+	}
+	if green.R != 0 || green.G != 255 || green.B != 0 {
+		t.Fatalf("pixel(1,0) = %v, want green", green)
+	}
+}
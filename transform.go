@@ -0,0 +1,392 @@
+package netpbm
+
+import (
+	"image/color"
+	"math"
+)
+
+// trigEpsilon snaps near-zero/near-unit trig components to exactly 0/±1 so
+// that right-angle rotations (90/180/270/360 degrees) don't pick up a
+// spurious extra row, column, or half-pixel offset from floating-point
+// residue in Cos/Sin.
+const trigEpsilon = 1e-9
+
+func snapUnit(v float64) float64 {
+	switch {
+	case math.Abs(v) < trigEpsilon:
+		return 0
+	case math.Abs(v-1) < trigEpsilon:
+		return 1
+	case math.Abs(v+1) < trigEpsilon:
+		return -1
+	default:
+		return v
+	}
+}
+
+// rotationTrig returns cos(theta) and sin(theta), snapped to exact 0/±1 near
+// the right angles so the rotation mapping below lands exactly on source
+// pixel centers instead of drifting by ~1e-16 past a pixel boundary.
+func rotationTrig(theta float64) (cosT, sinT float64) {
+	return snapUnit(math.Cos(theta)), snapUnit(math.Sin(theta))
+}
+
+// rotatedSize returns the axis-aligned bounding box (in pixels) that fully
+// contains a w x h image rotated by theta radians around its center.
+func rotatedSize(w, h int, theta float64) (int, int) {
+	cosT, sinT := rotationTrig(theta)
+	absCos, absSin := math.Abs(cosT), math.Abs(sinT)
+	newW := int(math.Ceil(float64(w)*absCos + float64(h)*absSin))
+	newH := int(math.Ceil(float64(w)*absSin + float64(h)*absCos))
+	return newW, newH
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// bilinearSample interpolates sample(x, y) at the fractional coordinates
+// (fx, fy), using fill for any of the four neighbors that fall outside the
+// w x h source bounds.
+func bilinearSample(w, h int, fill float64, sample func(x, y int) float64, fx, fy float64) float64 {
+	x0 := int(math.Floor(fx))
+	y0 := int(math.Floor(fy))
+	tx := fx - float64(x0)
+	ty := fy - float64(y0)
+
+	get := func(x, y int) float64 {
+		if x < 0 || x >= w || y < 0 || y >= h {
+			return fill
+		}
+		return sample(x, y)
+	}
+
+	top := lerp(get(x0, y0), get(x0+1, y0), tx)
+	bottom := lerp(get(x0, y0+1), get(x0+1, y0+1), tx)
+	return lerp(top, bottom, ty)
+}
+
+// sinc is the normalized sinc function used by the Lanczos kernel.
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// lanczos3Radius is the support radius, in source pixels, of the Lanczos3 kernel.
+const lanczos3Radius = 3
+
+func lanczosKernel(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	if x < -lanczos3Radius || x > lanczos3Radius {
+		return 0
+	}
+	return sinc(x) * sinc(x/lanczos3Radius)
+}
+
+// lanczosSample interpolates sample(x, y) at (fx, fy) with a windowed sinc
+// kernel of radius lanczos3Radius, using fill for out-of-bounds neighbors.
+func lanczosSample(w, h int, fill float64, sample func(x, y int) float64, fx, fy float64) float64 {
+	x0 := int(math.Floor(fx)) - lanczos3Radius + 1
+	x1 := int(math.Floor(fx)) + lanczos3Radius
+	y0 := int(math.Floor(fy)) - lanczos3Radius + 1
+	y1 := int(math.Floor(fy)) + lanczos3Radius
+
+	var sum, weightSum float64
+	for y := y0; y <= y1; y++ {
+		wy := lanczosKernel(fy - float64(y))
+		if wy == 0 {
+			continue
+		}
+		for x := x0; x <= x1; x++ {
+			wx := lanczosKernel(fx - float64(x))
+			if wx == 0 {
+				continue
+			}
+			weight := wx * wy
+			v := fill
+			if x >= 0 && x < w && y >= 0 && y < h {
+				v = sample(x, y)
+			}
+			sum += v * weight
+			weightSum += weight
+		}
+	}
+	if weightSum == 0 {
+		return fill
+	}
+	return sum / weightSum
+}
+
+// resizeAt maps destination pixel (dx, dy) of a newW x newH image back onto
+// the w x h source sampled by sample, using filter to interpolate.
+func resizeAt(w, h, newW, newH int, filter ResampleFilter, fill float64, sample func(x, y int) float64, dx, dy int) float64 {
+	fx := (float64(dx)+0.5)*float64(w)/float64(newW) - 0.5
+	fy := (float64(dy)+0.5)*float64(h)/float64(newH) - 0.5
+
+	switch filter {
+	case NearestNeighbor:
+		x := clampInt(int(math.Round(fx)), 0, w-1)
+		y := clampInt(int(math.Round(fy)), 0, h-1)
+		return sample(x, y)
+	case Lanczos3:
+		return lanczosSample(w, h, fill, sample, fx, fy)
+	default:
+		return bilinearSample(w, h, fill, sample, fx, fy)
+	}
+}
+
+// floydSteinberg thresholds the w x h grayscale buffer gray (0-255, modified
+// in place) to a binary image, diffusing the quantization error to
+// not-yet-visited neighbors per Floyd-Steinberg.
+func floydSteinberg(gray [][]float64, w, h int) [][]bool {
+	data := make([][]bool, h)
+	for y := range data {
+		data[y] = make([]bool, w)
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			old := gray[y][x]
+			black := old < 128
+			newVal := 255.0
+			if black {
+				newVal = 0
+			}
+			data[y][x] = black
+
+			err := old - newVal
+			if x+1 < w {
+				gray[y][x+1] += err * 7.0 / 16.0
+			}
+			if y+1 < h {
+				if x-1 >= 0 {
+					gray[y+1][x-1] += err * 3.0 / 16.0
+				}
+				gray[y+1][x] += err * 5.0 / 16.0
+				if x+1 < w {
+					gray[y+1][x+1] += err * 1.0 / 16.0
+				}
+			}
+		}
+	}
+	return data
+}
+
+// Rotate rotates the PBM image by angle degrees (clockwise as displayed,
+// with y growing downward) around
+// its center using bilinear resampling, growing the canvas to the rotated
+// image's axis-aligned bounding box. fill sets the value (black/white) used
+// for destination pixels that fall outside the source.
+func (pbm *PBM) Rotate(angle float64, fill bool) *PBM {
+	w, h := pbm.width, pbm.height
+	theta := angle * math.Pi / 180
+	newW, newH := rotatedSize(w, h, theta)
+
+	fillVal := 255.0
+	if fill {
+		fillVal = 0
+	}
+	sample := func(x, y int) float64 {
+		if pbm.data[y][x] {
+			return 0
+		}
+		return 255
+	}
+
+	data := make([][]bool, newH)
+	cx1, cy1 := (float64(w)-1)/2, (float64(h)-1)/2
+	cx2, cy2 := (float64(newW)-1)/2, (float64(newH)-1)/2
+	cosT, sinT := rotationTrig(theta)
+
+	for dy := 0; dy < newH; dy++ {
+		data[dy] = make([]bool, newW)
+		for dx := 0; dx < newW; dx++ {
+			rx := float64(dx) - cx2
+			ry := float64(dy) - cy2
+			fx := cosT*rx + sinT*ry + cx1
+			fy := -sinT*rx + cosT*ry + cy1
+			data[dy][dx] = bilinearSample(w, h, fillVal, sample, fx, fy) < 128
+		}
+	}
+
+	return &PBM{data: data, width: newW, height: newH, magicNumber: pbm.magicNumber}
+}
+
+// Resize scales the PBM image to newW x newH using filter on a grayscale
+// intermediate, then re-binarizes with Floyd-Steinberg error diffusion so
+// the output stays 1-bit without the severe aliasing plain thresholding
+// would cause.
+func (pbm *PBM) Resize(newW, newH int, filter ResampleFilter) *PBM {
+	w, h := pbm.width, pbm.height
+	sample := func(x, y int) float64 {
+		if pbm.data[y][x] {
+			return 0
+		}
+		return 255
+	}
+
+	gray := make([][]float64, newH)
+	for y := 0; y < newH; y++ {
+		gray[y] = make([]float64, newW)
+		for x := 0; x < newW; x++ {
+			gray[y][x] = resizeAt(w, h, newW, newH, filter, 255, sample, x, y)
+		}
+	}
+
+	return &PBM{data: floydSteinberg(gray, newW, newH), width: newW, height: newH, magicNumber: pbm.magicNumber}
+}
+
+// Rotate rotates the PGM image by angle degrees (clockwise as displayed,
+// with y growing downward) around
+// its center using bilinear resampling, growing the canvas to the rotated
+// image's axis-aligned bounding box. fill sets the sample value used for
+// destination pixels that fall outside the source.
+func (pgm *PGM) Rotate(angle float64, fill uint16) *PGM {
+	w, h := pgm.width, pgm.height
+	theta := angle * math.Pi / 180
+	newW, newH := rotatedSize(w, h, theta)
+
+	sample := func(x, y int) float64 { return float64(pgm.data[y][x]) }
+	maxVal := pgm.max
+	if maxVal == 0 {
+		maxVal = 255
+	}
+
+	data := make([][]uint16, newH)
+	cx1, cy1 := (float64(w)-1)/2, (float64(h)-1)/2
+	cx2, cy2 := (float64(newW)-1)/2, (float64(newH)-1)/2
+	cosT, sinT := rotationTrig(theta)
+
+	for dy := 0; dy < newH; dy++ {
+		data[dy] = make([]uint16, newW)
+		for dx := 0; dx < newW; dx++ {
+			rx := float64(dx) - cx2
+			ry := float64(dy) - cy2
+			fx := cosT*rx + sinT*ry + cx1
+			fy := -sinT*rx + cosT*ry + cy1
+			v := bilinearSample(w, h, float64(fill), sample, fx, fy)
+			data[dy][dx] = uint16(clampInt(int(math.Round(v)), 0, maxVal))
+		}
+	}
+
+	return &PGM{data: data, width: newW, height: newH, magicNumber: pgm.magicNumber, max: pgm.max}
+}
+
+// Resize scales the PGM image to newW x newH using filter.
+func (pgm *PGM) Resize(newW, newH int, filter ResampleFilter) *PGM {
+	w, h := pgm.width, pgm.height
+	sample := func(x, y int) float64 { return float64(pgm.data[y][x]) }
+	maxVal := pgm.max
+	if maxVal == 0 {
+		maxVal = 255
+	}
+
+	data := make([][]uint16, newH)
+	for y := 0; y < newH; y++ {
+		data[y] = make([]uint16, newW)
+		for x := 0; x < newW; x++ {
+			v := resizeAt(w, h, newW, newH, filter, 0, sample, x, y)
+			data[y][x] = uint16(clampInt(int(math.Round(v)), 0, maxVal))
+		}
+	}
+
+	return &PGM{data: data, width: newW, height: newH, magicNumber: pgm.magicNumber, max: pgm.max}
+}
+
+// Rotate rotates the PPM image by angle degrees (clockwise as displayed,
+// with y growing downward) around
+// its center using bilinear resampling, growing the canvas to the rotated
+// image's axis-aligned bounding box. fill sets the color used for
+// destination pixels that fall outside the source.
+func (ppm *PPM) Rotate(angle float64, fill color.RGBA64) *PPM {
+	w, h := ppm.width, ppm.height
+	theta := angle * math.Pi / 180
+	newW, newH := rotatedSize(w, h, theta)
+
+	channel := func(c func(p color.RGBA64) uint16) func(x, y int) float64 {
+		return func(x, y int) float64 { return float64(c(ppm.data[y][x])) }
+	}
+	sampleR := channel(func(p color.RGBA64) uint16 { return p.R })
+	sampleG := channel(func(p color.RGBA64) uint16 { return p.G })
+	sampleB := channel(func(p color.RGBA64) uint16 { return p.B })
+
+	maxVal := ppm.max
+	if maxVal == 0 {
+		maxVal = 255
+	}
+
+	data := make([][]color.RGBA64, newH)
+	cx1, cy1 := (float64(w)-1)/2, (float64(h)-1)/2
+	cx2, cy2 := (float64(newW)-1)/2, (float64(newH)-1)/2
+	cosT, sinT := rotationTrig(theta)
+
+	for dy := 0; dy < newH; dy++ {
+		data[dy] = make([]color.RGBA64, newW)
+		for dx := 0; dx < newW; dx++ {
+			rx := float64(dx) - cx2
+			ry := float64(dy) - cy2
+			fx := cosT*rx + sinT*ry + cx1
+			fy := -sinT*rx + cosT*ry + cy1
+
+			r := bilinearSample(w, h, float64(fill.R), sampleR, fx, fy)
+			g := bilinearSample(w, h, float64(fill.G), sampleG, fx, fy)
+			b := bilinearSample(w, h, float64(fill.B), sampleB, fx, fy)
+			data[dy][dx] = color.RGBA64{
+				R: uint16(clampInt(int(math.Round(r)), 0, maxVal)),
+				G: uint16(clampInt(int(math.Round(g)), 0, maxVal)),
+				B: uint16(clampInt(int(math.Round(b)), 0, maxVal)),
+				A: 0xffff,
+			}
+		}
+	}
+
+	return &PPM{data: data, width: newW, height: newH, magicNumber: ppm.magicNumber, max: ppm.max}
+}
+
+// Resize scales the PPM image to newW x newH using filter.
+func (ppm *PPM) Resize(newW, newH int, filter ResampleFilter) *PPM {
+	w, h := ppm.width, ppm.height
+	channel := func(c func(p color.RGBA64) uint16) func(x, y int) float64 {
+		return func(x, y int) float64 { return float64(c(ppm.data[y][x])) }
+	}
+	sampleR := channel(func(p color.RGBA64) uint16 { return p.R })
+	sampleG := channel(func(p color.RGBA64) uint16 { return p.G })
+	sampleB := channel(func(p color.RGBA64) uint16 { return p.B })
+
+	maxVal := ppm.max
+	if maxVal == 0 {
+		maxVal = 255
+	}
+
+	data := make([][]color.RGBA64, newH)
+	for y := 0; y < newH; y++ {
+		data[y] = make([]color.RGBA64, newW)
+		for x := 0; x < newW; x++ {
+			r := resizeAt(w, h, newW, newH, filter, 0, sampleR, x, y)
+			g := resizeAt(w, h, newW, newH, filter, 0, sampleG, x, y)
+			b := resizeAt(w, h, newW, newH, filter, 0, sampleB, x, y)
+			data[y][x] = color.RGBA64{
+				R: uint16(clampInt(int(math.Round(r)), 0, maxVal)),
+				G: uint16(clampInt(int(math.Round(g)), 0, maxVal)),
+				B: uint16(clampInt(int(math.Round(b)), 0, maxVal)),
+				A: 0xffff,
+			}
+		}
+	}
+
+	return &PPM{data: data, width: newW, height: newH, magicNumber: ppm.magicNumber, max: ppm.max}
+}
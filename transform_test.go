@@ -0,0 +1,127 @@
+package netpbm
+
+import (
+	"bufio"
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+// TestPGMRotateIdentity checks that a 0-degree rotation returns the source
+// pixels unchanged on the same size canvas.
+func TestPGMRotateIdentity(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader([]byte("P2\n2 2\n255\n10 20\n30 40\n")))
+	pgm, err := ReadPGM(r)
+	if err != nil {
+		t.Fatalf("ReadPGM: %v", err)
+	}
+
+	rotated := pgm.Rotate(0, 0)
+	if w, h := rotated.Size(); w != 2 || h != 2 {
+		t.Fatalf("size = %dx%d, want 2x2", w, h)
+	}
+	want := [][]uint16{{10, 20}, {30, 40}}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if got := rotated.Get(x, y); got != want[y][x] {
+				t.Fatalf("pixel(%d,%d) = %d, want %d", x, y, got, want[y][x])
+			}
+		}
+	}
+}
+
+// TestPGMRotate180 checks that rotating a square image by 180 degrees keeps
+// the same canvas size and reverses the pixels, guarding against the
+// rotatedSize bounding-box math picking up a spurious extra row/column from
+// floating-point residue in Cos/Sin at right angles.
+func TestPGMRotate180(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader([]byte("P2\n2 2\n255\n10 20\n30 40\n")))
+	pgm, err := ReadPGM(r)
+	if err != nil {
+		t.Fatalf("ReadPGM: %v", err)
+	}
+
+	rotated := pgm.Rotate(180, 0)
+	if w, h := rotated.Size(); w != 2 || h != 2 {
+		t.Fatalf("size = %dx%d, want 2x2", w, h)
+	}
+	want := [][]uint16{{40, 30}, {20, 10}}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if got := rotated.Get(x, y); got != want[y][x] {
+				t.Fatalf("pixel(%d,%d) = %d, want %d", x, y, got, want[y][x])
+			}
+		}
+	}
+}
+
+// TestPGMRotate270And360 checks the remaining right angles called out by
+// the backlog request don't inflate the canvas either.
+func TestPGMRotate270And360(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader([]byte("P2\n2 2\n255\n10 20\n30 40\n")))
+	pgm, err := ReadPGM(r)
+	if err != nil {
+		t.Fatalf("ReadPGM: %v", err)
+	}
+
+	for _, angle := range []float64{270, 360} {
+		rotated := pgm.Rotate(angle, 0)
+		if w, h := rotated.Size(); w != 2 || h != 2 {
+			t.Fatalf("angle %v: size = %dx%d, want 2x2", angle, w, h)
+		}
+	}
+}
+
+// TestPGMResizeDimensions checks that Resize produces exactly the requested
+// output dimensions for every filter.
+func TestPGMResizeDimensions(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader([]byte("P2\n2 2\n255\n10 20\n30 40\n")))
+	pgm, err := ReadPGM(r)
+	if err != nil {
+		t.Fatalf("ReadPGM: %v", err)
+	}
+
+	for _, filter := range []ResampleFilter{NearestNeighbor, Bilinear, Lanczos3} {
+		resized := pgm.Resize(5, 3, filter)
+		if w, h := resized.Size(); w != 5 || h != 3 {
+			t.Fatalf("filter %v: size = %dx%d, want 5x3", filter, w, h)
+		}
+	}
+}
+
+// TestPBMResizeStaysBinary checks that PBM's Floyd-Steinberg dithered resize
+// produces the requested dimensions; its result is a *PBM, whose bool
+// pixels are binary by type.
+func TestPBMResizeStaysBinary(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader([]byte("P1\n2 2\n1 0\n0 1\n")))
+	pbm, err := ReadPBM(r)
+	if err != nil {
+		t.Fatalf("ReadPBM: %v", err)
+	}
+
+	resized := pbm.Resize(4, 4, Bilinear)
+	if w, h := resized.Size(); w != 4 || h != 4 {
+		t.Fatalf("size = %dx%d, want 4x4", w, h)
+	}
+}
+
+// TestPPMRotate180 checks the PPM Rotate path also keeps the canvas size
+// fixed at 180 degrees for a square source.
+func TestPPMRotate180(t *testing.T) {
+	stream := "P3\n2 2\n255\n10 20 30\n40 50 60\n70 80 90\n100 110 120\n"
+	r := bufio.NewReader(bytes.NewReader([]byte(stream)))
+	ppm, err := ReadPPM(r)
+	if err != nil {
+		t.Fatalf("ReadPPM: %v", err)
+	}
+
+	rotated := ppm.Rotate(180, color.RGBA64{})
+	if w, h := rotated.Size(); w != 2 || h != 2 {
+		t.Fatalf("size = %dx%d, want 2x2", w, h)
+	}
+	got := rotated.Get(0, 0)
+	want := ppm.Get(1, 1)
+	if got != want {
+		t.Fatalf("pixel(0,0) = %v, want %v (source pixel(1,1))", got, want)
+	}
+}